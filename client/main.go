@@ -1,31 +1,55 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
-	"io"
+	"flag"
 	"log"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
 )
 
+const authorizationHeaderKey = "X-Auth-Token"
+
 func main() {
-	srv := newServer()
-	err := srv.start(":8080")
+	metricsAddr := flag.String("metrics-addr", "", "address to serve /metrics on; disabled if empty")
+	flag.Parse()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("cannot build logger: %s", err)
+	}
+	defer logger.Sync()
+
+	if *metricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.Handler())
+			if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+				logger.Error("metrics server stopped", zap.Error(err))
+			}
+		}()
+	}
+
+	srv := newServer(logger)
+	err = srv.start(":8080")
 	if err != nil {
 		log.Fatalf("cannot start server: %s", err)
 	}
 }
 
 type Server struct {
-	router *gin.Engine
+	router    *gin.Engine
+	requester *Requester
+	logger    *zap.Logger
 }
 
-func newServer() *Server {
-	server := &Server{}
+func newServer(logger *zap.Logger) *Server {
+	server := &Server{
+		requester: NewRequester("http://factor-server:8080", "/factor"),
+		logger:    logger,
+	}
 	server.setupRouter()
 	return server
 }
@@ -35,7 +59,8 @@ func (server *Server) start(addr string) error {
 }
 
 func (server *Server) setupRouter() {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Recovery(), metricsMiddleware(), requestLogger(server.logger))
 	router.POST("/factor", server.reqWithToken)
 	server.router = router
 }
@@ -55,65 +80,15 @@ func (server *Server) reqWithToken(ctx *gin.Context) {
 		return
 	}
 
-	// Read the service token
-	token, err := readToken()
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	body, err := json.Marshal(freq)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", "http://factor-server:8080/factor", bytes.NewBuffer(body))
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-	}
-
-	req.Header.Set("X-Auth-Token", string(token))
-	serverResp, err := client.Do(req)
-	if err != nil {
+	var factResp factorResponse
+	if err := server.requester.Do(ctx, freq, &factResp); err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	defer serverResp.Body.Close()
 
-	if serverResp.StatusCode == http.StatusForbidden {
-		err := errors.New("the HTTP request was not authenticated, downstream service responded with 403")
-		ctx.JSON(http.StatusForbidden, errorResponse(err))
-		return
-	}
-
-	if serverResp.StatusCode == http.StatusOK {
-		respBody, err := io.ReadAll(serverResp.Body)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-
-		var factResp factorResponse
-		err = json.Unmarshal(respBody, &factResp)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusOK, factResp)
-	}
+	ctx.JSON(http.StatusOK, factResp)
 }
 
 func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
-
-func readToken() ([]byte, error) {
-	file, err := os.Open("/var/run/secrets/tokens/factor-token")
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	return io.ReadAll(file)
-}
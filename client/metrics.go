@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests, labeled by route and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "status"})
+
+	upstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "upstream_request_duration_seconds",
+		Help:    "Latency of calls to the upstream factor-server, labeled by status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"status"})
+
+	inFlightRequests = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_in_flight_requests",
+		Help: "Number of HTTP requests currently being served.",
+	})
+)
+
+func statusLabel(status int) string {
+	return strconv.Itoa(status)
+}
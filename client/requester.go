@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	tokenPath = "/var/run/secrets/tokens/factor-token"
+
+	defaultTimeout    = 5 * time.Second
+	defaultMaxRetries = 3
+	defaultBaseDelay  = 100 * time.Millisecond
+)
+
+// Requester calls a single upstream endpoint, taking care of the
+// boilerplate every call to it needs: JSON marshaling, auth token
+// attachment, timeouts, retries and transparent token refresh. Future
+// upstream endpoints can reuse it instead of duplicating this plumbing.
+type Requester struct {
+	// Addr is the upstream host, e.g. "http://factor-server:8080".
+	Addr string
+	// Endpoint is the path called on Addr, e.g. "/factor".
+	Endpoint string
+	// Client performs the HTTP round trip. Defaults to a client with
+	// defaultTimeout and TLSConfig applied if left nil.
+	Client *http.Client
+	// TLSConfig, if set, is applied to Client's transport when Client is nil.
+	TLSConfig *tls.Config
+	// MaxRetries bounds the number of retry attempts for idempotent
+	// failures. Zero uses defaultMaxRetries.
+	MaxRetries int
+	// AdditionalData lets callers mutate the outgoing request, e.g. to add
+	// extra headers, before it is sent.
+	AdditionalData func(*http.Request)
+
+	// tokenMu guards token: a single Requester is shared across concurrent
+	// handler goroutines, and both readToken and the 401/403 retry path
+	// read/write it.
+	tokenMu sync.Mutex
+	token   []byte
+}
+
+// NewRequester builds a Requester for addr/endpoint with sane defaults.
+func NewRequester(addr, endpoint string) *Requester {
+	return &Requester{
+		Addr:       addr,
+		Endpoint:   endpoint,
+		MaxRetries: defaultMaxRetries,
+	}
+}
+
+// Do marshals body as JSON, POSTs it to r.Addr+r.Endpoint with the
+// projected service-account token attached, and unmarshals the response
+// into respOut. Idempotent failures (network errors, 5xx) are retried with
+// exponential backoff and jitter. A 401/403 response discards the cached
+// token so the next attempt re-reads it in case it has rotated on disk; such
+// retries count against the same MaxRetries budget.
+func (r *Requester) Do(ctx context.Context, body, respOut any) error {
+	client := r.client()
+	start := time.Now()
+	status := "error"
+	defer func() {
+		upstreamDuration.WithLabelValues(status).Observe(time.Since(start).Seconds())
+	}()
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal request body: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= r.maxRetries(); attempt++ {
+		if attempt > 0 {
+			if err := sleepBackoff(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		resp, err := r.doOnce(ctx, client, payload)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		status = statusLabel(resp.StatusCode)
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			resp.Body.Close()
+			r.clearToken() // force a re-read in case the token rotated
+			lastErr = fmt.Errorf("upstream returned status %d", resp.StatusCode)
+			continue
+		case resp.StatusCode >= http.StatusInternalServerError:
+			resp.Body.Close()
+			lastErr = fmt.Errorf("upstream returned status %d", resp.StatusCode)
+			continue
+		case resp.StatusCode != http.StatusOK:
+			defer resp.Body.Close()
+			respBody, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("upstream returned status %d: %s", resp.StatusCode, respBody)
+		}
+
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(respOut); err != nil {
+			return fmt.Errorf("decode response body: %w", err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("upstream call failed after %d attempts: %w", r.maxRetries()+1, lastErr)
+}
+
+// doOnce performs a single attempt of the upstream call.
+func (r *Requester) doOnce(ctx context.Context, client *http.Client, payload []byte) (*http.Response, error) {
+	token, err := r.readToken()
+	if err != nil {
+		return nil, fmt.Errorf("read service account token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.Addr+r.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(authorizationHeaderKey, string(token))
+
+	if r.AdditionalData != nil {
+		r.AdditionalData(req)
+	}
+
+	return client.Do(req)
+}
+
+// readToken returns the cached projected token, re-reading it from disk if
+// it has not been read yet (startup, or after a 401/403 cleared the cache).
+func (r *Requester) readToken() ([]byte, error) {
+	r.tokenMu.Lock()
+	defer r.tokenMu.Unlock()
+
+	if r.token != nil {
+		return r.token, nil
+	}
+
+	token, err := readToken()
+	if err != nil {
+		return nil, err
+	}
+	r.token = token
+	return token, nil
+}
+
+// clearToken discards the cached token so the next readToken call re-reads
+// it from disk.
+func (r *Requester) clearToken() {
+	r.tokenMu.Lock()
+	defer r.tokenMu.Unlock()
+	r.token = nil
+}
+
+func (r *Requester) client() *http.Client {
+	if r.Client != nil {
+		return r.Client
+	}
+	return &http.Client{
+		Timeout: defaultTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: r.TLSConfig,
+		},
+	}
+}
+
+func (r *Requester) maxRetries() int {
+	if r.MaxRetries > 0 {
+		return r.MaxRetries
+	}
+	return defaultMaxRetries
+}
+
+// sleepBackoff waits an exponentially increasing, jittered delay before
+// retry attempt, returning ctx.Err() if ctx is cancelled first.
+func sleepBackoff(ctx context.Context, attempt int) error {
+	delay := defaultBaseDelay * time.Duration(1<<uint(attempt-1))
+	delay += time.Duration(rand.Int63n(int64(defaultBaseDelay)))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// readToken reads the kubelet-projected service account token from disk.
+func readToken() ([]byte, error) {
+	file, err := os.Open(tokenPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
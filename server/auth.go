@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errNotAuthenticated is returned by Authenticator implementations when the
+// token is well-formed but does not resolve to a valid identity.
+var errNotAuthenticated = errors.New("token is not authenticated")
+
+// UserInfo describes the identity an Authenticator resolved a token to.
+type UserInfo struct {
+	Username  string
+	UID       string
+	Groups    []string
+	Extra     map[string][]string
+	Audiences []string
+}
+
+// Authenticator verifies a bearer token and returns the identity it belongs
+// to. Implementations back onto different identity providers (the
+// Kubernetes TokenReview API, an OIDC issuer, an RFC 7662 introspection
+// endpoint, ...) behind a single interface so tokenReviewMiddleware does not
+// need to know which one is in use.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*UserInfo, error)
+}
+
+// newAuthenticator builds the Authenticator selected by cfg.Backend, wrapped
+// in a cache so repeat requests bearing the same token don't all hit the
+// backend.
+func newAuthenticator(cfg *Config) (Authenticator, error) {
+	backend, err := newBackendAuthenticator(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := newLRUReviewCache(cfg.Cache.Size, cfg.Cache.TTL, cfg.Cache.NegativeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("build review cache: %w", err)
+	}
+
+	return newCachingAuthenticator(backend, cache), nil
+}
+
+// newBackendAuthenticator builds the uncached Authenticator for cfg.Backend.
+func newBackendAuthenticator(cfg *Config) (Authenticator, error) {
+	switch cfg.Backend {
+	case BackendTokenReview:
+		clientset, err := setupKubeClient()
+		if err != nil {
+			return nil, fmt.Errorf("setup kube client: %w", err)
+		}
+		return newTokenReviewAuthenticator(clientset, cfg.AllowedAudiences), nil
+	case BackendOIDC:
+		return newOIDCAuthenticator(cfg.OIDC.IssuerURL, cfg.OIDC.JWKSURL, cfg.AllowedAudiences)
+	case BackendIntrospect:
+		return newIntrospectAuthenticator(cfg.Introspection.Endpoint, cfg.Introspection.ClientID, cfg.Introspection.ClientSecret, cfg.AllowedAudiences), nil
+	default:
+		return nil, fmt.Errorf("unknown auth backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// cachingAuthenticator wraps another Authenticator with a ReviewCache so
+// that repeated requests bearing the same token don't all reach the
+// backend (the kube-apiserver, an OIDC issuer, or an introspection
+// endpoint).
+type cachingAuthenticator struct {
+	next  Authenticator
+	cache ReviewCache
+}
+
+// newCachingAuthenticator returns an Authenticator that consults cache
+// before falling back to next.
+func newCachingAuthenticator(next Authenticator, cache ReviewCache) *cachingAuthenticator {
+	return &cachingAuthenticator{next: next, cache: cache}
+}
+
+// Authenticate implements Authenticator.
+func (a *cachingAuthenticator) Authenticate(ctx context.Context, token string) (*UserInfo, error) {
+	if user, authenticated, found := a.cache.Get(token); found {
+		cacheHits.Inc()
+		if !authenticated {
+			return nil, errNotAuthenticated
+		}
+		return user, nil
+	}
+	cacheMisses.Inc()
+
+	user, err := a.next.Authenticate(ctx, token)
+	switch {
+	case err == nil:
+		a.cache.Set(token, user, true)
+		return user, nil
+	case errors.Is(err, errNotAuthenticated):
+		a.cache.Set(token, nil, false)
+		return nil, err
+	default:
+		cacheErrors.Inc()
+		return nil, err
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// tokenReviewAuthenticator authenticates bearer tokens against the
+// Kubernetes TokenReview API.
+type tokenReviewAuthenticator struct {
+	clientset        *kubernetes.Clientset
+	allowedAudiences []string
+}
+
+// newTokenReviewAuthenticator returns an Authenticator backed by the
+// TokenReview API of clientset, accepting tokens whose intersected
+// audiences overlap allowedAudiences.
+func newTokenReviewAuthenticator(clientset *kubernetes.Clientset, allowedAudiences []string) *tokenReviewAuthenticator {
+	return &tokenReviewAuthenticator{
+		clientset:        clientset,
+		allowedAudiences: allowedAudiences,
+	}
+}
+
+// Authenticate submits token to the kube-apiserver's TokenReview API and
+// returns the resolved identity.
+func (a *tokenReviewAuthenticator) Authenticate(ctx context.Context, token string) (*UserInfo, error) {
+	tokenReview := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: a.allowedAudiences,
+		},
+	}
+
+	review, err := a.clientset.AuthenticationV1().TokenReviews().Create(ctx, tokenReview, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !review.Status.Authenticated || !validateAudiences(review.Status.Audiences, a.allowedAudiences) {
+		return nil, errNotAuthenticated
+	}
+
+	user := review.Status.User
+	return &UserInfo{
+		Username:  user.Username,
+		UID:       user.UID,
+		Groups:    user.Groups,
+		Extra:     convertExtra(user.Extra),
+		Audiences: review.Status.Audiences,
+	}, nil
+}
+
+// convertExtra adapts authentication/v1's ExtraValue map to a plain
+// map[string][]string for use in UserInfo.
+func convertExtra(extra map[string]authenticationv1.ExtraValue) map[string][]string {
+	if extra == nil {
+		return nil
+	}
+	out := make(map[string][]string, len(extra))
+	for k, v := range extra {
+		out[k] = v
+	}
+	return out
+}
+
+// validateAudiences reports whether any of audiences is present in allowed.
+func validateAudiences(audiences, allowed []string) bool {
+	for _, v := range audiences {
+		for _, a := range allowed {
+			if v == a {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	defaultCacheSize        = 4096
+	defaultCacheTTL         = 60 * time.Second
+	defaultNegativeCacheTTL = 10 * time.Second
+)
+
+var (
+	cacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tokenreview_cache_hits_total",
+		Help: "Number of authentication lookups served from the in-process cache.",
+	})
+	cacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tokenreview_cache_misses_total",
+		Help: "Number of authentication lookups that required calling the backend.",
+	})
+	cacheErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tokenreview_cache_errors_total",
+		Help: "Number of authentication lookups that failed for reasons other than an invalid token.",
+	})
+)
+
+// ReviewCache caches the outcome of authenticating a token, keyed by a hash
+// of the token so raw tokens are never retained longer than the request
+// that presented them. Implementations must be safe for concurrent use.
+// The interface exists so the default in-process cache can later be
+// swapped for a shared backend (e.g. Redis) without touching callers.
+type ReviewCache interface {
+	// Get returns the cached outcome for token. found is false on a cache
+	// miss or expired entry; authenticated is only meaningful when found
+	// is true.
+	Get(token string) (user *UserInfo, authenticated, found bool)
+	// Set records the outcome of authenticating token.
+	Set(token string, user *UserInfo, authenticated bool)
+}
+
+// cacheEntry is the value stored per token in lruReviewCache.
+type cacheEntry struct {
+	user          *UserInfo
+	authenticated bool
+	expiresAt     time.Time
+}
+
+// lruReviewCache is the default ReviewCache: a size-bounded LRU with
+// separate TTLs for positive and negative results, so failed tokens don't
+// get hammered against the backend but also don't linger as long as a
+// verified identity.
+type lruReviewCache struct {
+	entries     *lru.Cache[string, cacheEntry]
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// newLRUReviewCache builds a ReviewCache holding at most size entries, with
+// successful authentications cached for ttl and failures for negativeTTL.
+// Non-positive values fall back to the package defaults.
+func newLRUReviewCache(size int, ttl, negativeTTL time.Duration) (*lruReviewCache, error) {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	if negativeTTL <= 0 {
+		negativeTTL = defaultNegativeCacheTTL
+	}
+
+	entries, err := lru.New[string, cacheEntry](size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lruReviewCache{entries: entries, ttl: ttl, negativeTTL: negativeTTL}, nil
+}
+
+// Get implements ReviewCache.
+func (c *lruReviewCache) Get(token string) (*UserInfo, bool, bool) {
+	key := cacheKey(token)
+
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		c.entries.Remove(key)
+		return nil, false, false
+	}
+
+	return entry.user, entry.authenticated, true
+}
+
+// Set implements ReviewCache.
+func (c *lruReviewCache) Set(token string, user *UserInfo, authenticated bool) {
+	ttl := c.ttl
+	if !authenticated {
+		ttl = c.negativeTTL
+	}
+
+	c.entries.Add(cacheKey(token), cacheEntry{
+		user:          user,
+		authenticated: authenticated,
+		expiresAt:     time.Now().Add(ttl),
+	})
+}
+
+// cacheKey hashes token so the cache never holds raw bearer tokens in memory.
+func cacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
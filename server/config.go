@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AuthBackend selects which Authenticator implementation the server uses.
+type AuthBackend string
+
+const (
+	// BackendTokenReview authenticates against the Kubernetes TokenReview API.
+	BackendTokenReview AuthBackend = "tokenreview"
+	// BackendOIDC verifies tokens offline against an OIDC issuer's JWKS.
+	BackendOIDC AuthBackend = "oidc"
+	// BackendIntrospect authenticates via RFC 7662 OAuth2 token introspection.
+	BackendIntrospect AuthBackend = "introspect"
+)
+
+// Config holds the settings needed to build the configured Authenticator.
+type Config struct {
+	Backend AuthBackend `yaml:"backend"`
+
+	// AllowedAudiences lists the audiences accepted regardless of backend.
+	AllowedAudiences []string `yaml:"allowedAudiences"`
+
+	OIDC struct {
+		IssuerURL string `yaml:"issuerURL"`
+		JWKSURL   string `yaml:"jwksURL"`
+	} `yaml:"oidc"`
+
+	Introspection struct {
+		Endpoint     string `yaml:"endpoint"`
+		ClientID     string `yaml:"clientID"`
+		ClientSecret string `yaml:"clientSecret"`
+	} `yaml:"introspection"`
+
+	// Cache configures the in-process TokenReview result cache. Zero
+	// values fall back to the defaults in cache.go.
+	Cache struct {
+		Size        int           `yaml:"size"`
+		TTL         time.Duration `yaml:"ttl"`
+		NegativeTTL time.Duration `yaml:"negativeTTL"`
+	} `yaml:"cache"`
+
+	// FactorMaxDivisors bounds how many divisors factor() will enumerate.
+	// Zero falls back to defaultMaxDivisors in factor.go.
+	FactorMaxDivisors int `yaml:"factorMaxDivisors"`
+
+	// SyncMaxInput bounds how large an nr POST /factor will compute
+	// synchronously; larger values must go through POST /factor/jobs.
+	// Zero falls back to defaultSyncMaxInput in jobs.go.
+	SyncMaxInput int64 `yaml:"syncMaxInput"`
+
+	// Jobs configures the async factorization job subsystem.
+	Jobs struct {
+		Workers    int `yaml:"workers"`
+		QueueSize  int `yaml:"queueSize"`
+		MaxPerUser int `yaml:"maxPerUser"`
+	} `yaml:"jobs"`
+}
+
+// loadConfig reads the server configuration from a YAML file at path, then
+// lets any set environment variable override the matching field. An empty
+// path skips the file and uses environment variables only.
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parse config file: %w", err)
+		}
+	}
+
+	applyEnvOverrides(cfg)
+
+	if cfg.Backend == "" {
+		cfg.Backend = BackendTokenReview
+	}
+	if len(cfg.AllowedAudiences) == 0 {
+		cfg.AllowedAudiences = []string{audience}
+	}
+	if cfg.Jobs.Workers <= 0 {
+		cfg.Jobs.Workers = defaultJobWorkers
+	}
+	if cfg.Jobs.QueueSize <= 0 {
+		cfg.Jobs.QueueSize = defaultJobQueueSize
+	}
+	if cfg.Jobs.MaxPerUser <= 0 {
+		cfg.Jobs.MaxPerUser = defaultJobMaxPerUser
+	}
+
+	return cfg, nil
+}
+
+// applyEnvOverrides lets environment variables override values loaded from the
+// config file, matching the precedence conventions used elsewhere in ops tooling.
+func applyEnvOverrides(cfg *Config) {
+	if v := os.Getenv("AUTH_BACKEND"); v != "" {
+		cfg.Backend = AuthBackend(v)
+	}
+	if v := os.Getenv("OIDC_ISSUER_URL"); v != "" {
+		cfg.OIDC.IssuerURL = v
+	}
+	if v := os.Getenv("OIDC_JWKS_URL"); v != "" {
+		cfg.OIDC.JWKSURL = v
+	}
+	if v := os.Getenv("INTROSPECTION_ENDPOINT"); v != "" {
+		cfg.Introspection.Endpoint = v
+	}
+	if v := os.Getenv("INTROSPECTION_CLIENT_ID"); v != "" {
+		cfg.Introspection.ClientID = v
+	}
+	if v := os.Getenv("INTROSPECTION_CLIENT_SECRET"); v != "" {
+		cfg.Introspection.ClientSecret = v
+	}
+	if v := os.Getenv("CACHE_SIZE"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil {
+			cfg.Cache.Size = size
+		}
+	}
+	if v := os.Getenv("CACHE_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil {
+			cfg.Cache.TTL = ttl
+		}
+	}
+	if v := os.Getenv("CACHE_NEGATIVE_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil {
+			cfg.Cache.NegativeTTL = ttl
+		}
+	}
+	if v := os.Getenv("FACTOR_MAX_DIVISORS"); v != "" {
+		if max, err := strconv.Atoi(v); err == nil {
+			cfg.FactorMaxDivisors = max
+		}
+	}
+	if v := os.Getenv("SYNC_MAX_INPUT"); v != "" {
+		if max, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.SyncMaxInput = max
+		}
+	}
+	if v := os.Getenv("JOBS_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Jobs.Workers = n
+		}
+	}
+	if v := os.Getenv("JOBS_QUEUE_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Jobs.QueueSize = n
+		}
+	}
+	if v := os.Getenv("JOBS_MAX_PER_USER"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Jobs.MaxPerUser = n
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// defaultMaxDivisors bounds how many divisors factor will enumerate before
+// giving up, to avoid exhausting memory on highly composite inputs.
+const defaultMaxDivisors = 100000
+
+// maxDivisors is the effective divisor cap, overridable at startup via
+// Config.FactorMaxDivisors.
+var maxDivisors = defaultMaxDivisors
+
+// smallPrimeWheel lists every prime up to 1e5, used to strip small factors
+// before falling back to Miller-Rabin/Pollard's rho for the cofactor.
+var smallPrimeWheel = sieve(100000)
+
+// millerRabinWitnesses are deterministic for every n < 2^64 (see Pomerance,
+// Selfridge & Wagstaff / Jaeschke).
+var millerRabinWitnesses = []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+
+// factor calculates all factors of a given positive integer nr. Small prime
+// factors are stripped with smallPrimeWheel; the remaining cofactor (if any)
+// is split into primes with Miller-Rabin primality testing and Pollard's
+// rho. The returned slice is sorted ascending.
+func factor(nr int64) ([]int64, error) {
+	return factorContext(context.Background(), nr)
+}
+
+// factorContext is factor, but abortable via ctx: jobs.go uses this so
+// DELETE /factor/jobs/:id actually stops the worker mid-computation instead
+// of only flipping the stored status.
+func factorContext(ctx context.Context, nr int64) ([]int64, error) {
+	if nr < 1 {
+		return nil, errors.New("factors of 0 not computed, please provide a positive integer greater than 0")
+	}
+
+	primeFactors := make(map[int64]int)
+	remaining := uint64(nr)
+
+	for _, p := range smallPrimeWheel {
+		up := uint64(p)
+		if up*up > remaining {
+			break
+		}
+		for remaining%up == 0 {
+			primeFactors[p]++
+			remaining /= up
+		}
+	}
+
+	if remaining > 1 {
+		if err := factorizeLargeCofactor(ctx, remaining, primeFactors); err != nil {
+			return nil, err
+		}
+	}
+
+	divisors, err := expandDivisors(primeFactors)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(divisors, func(i, j int) bool { return divisors[i] < divisors[j] })
+	return divisors, nil
+}
+
+// factorizeLargeCofactor recursively splits n (the cofactor left after
+// trial division by smallPrimeWheel) into primes, appending their multiplicity to
+// primeFactors. It checks ctx before each Pollard's rho attempt since that
+// is where the bulk of the CPU time goes.
+func factorizeLargeCofactor(ctx context.Context, n uint64, primeFactors map[int64]int) error {
+	if n == 1 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if isPrime(n) {
+		primeFactors[int64(n)]++
+		return nil
+	}
+
+	d, err := pollardRho(ctx, n)
+	if err != nil {
+		return err
+	}
+	if err := factorizeLargeCofactor(ctx, d, primeFactors); err != nil {
+		return err
+	}
+	return factorizeLargeCofactor(ctx, n/d, primeFactors)
+}
+
+// expandDivisors generates every divisor implied by primeFactors, bailing
+// out once the count would exceed maxDivisors.
+func expandDivisors(primeFactors map[int64]int) ([]int64, error) {
+	divisors := []int64{1}
+
+	for p, e := range primeFactors {
+		if len(divisors)*(e+1) > maxDivisors {
+			return nil, fmt.Errorf("factorization has more than %d divisors, refusing to enumerate", maxDivisors)
+		}
+
+		n := len(divisors)
+		for i, pp := 1, p; i <= e; i, pp = i+1, pp*p {
+			for j := 0; j < n; j++ {
+				divisors = append(divisors, divisors[j]*pp)
+			}
+		}
+	}
+
+	return divisors, nil
+}
+
+// isPrime is a deterministic Miller-Rabin primality test, correct for every
+// n representable as a uint64.
+func isPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for _, p := range millerRabinWitnesses {
+		if n == p {
+			return true
+		}
+		if n%p == 0 {
+			return false
+		}
+	}
+
+	d, r := n-1, 0
+	for d%2 == 0 {
+		d /= 2
+		r++
+	}
+
+	for _, a := range millerRabinWitnesses {
+		x := powMod(a, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+
+		composite := true
+		for i := 0; i < r-1; i++ {
+			x = mulMod(x, x, n)
+			if x == n-1 {
+				composite = false
+				break
+			}
+		}
+		if composite {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pollardRho finds a non-trivial factor of the composite n using Pollard's
+// rho with Brent's cycle-finding variant, restarting with a new f(x) =
+// x*x+c mod n whenever a run fails to split n. It checks ctx every batch so
+// a cancellation lands within a bounded number of modular multiplications
+// instead of only at the next recursive call.
+func pollardRho(ctx context.Context, n uint64) (uint64, error) {
+	if n%2 == 0 {
+		return 2, nil
+	}
+
+	const batchSize = 128
+
+	for c := uint64(1); c < 64; c++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		f := func(x uint64) uint64 { return (mulMod(x, x, n) + c) % n }
+
+		var x, ys uint64
+		y, g, r, q := uint64(2), uint64(1), 1, uint64(1)
+
+		for g == 1 {
+			x = y
+			for i := 0; i < r; i++ {
+				y = f(y)
+			}
+
+			for k := 0; k < r && g == 1; k += batchSize {
+				if err := ctx.Err(); err != nil {
+					return 0, err
+				}
+
+				ys = y
+				steps := batchSize
+				if r-k < steps {
+					steps = r - k
+				}
+				for i := 0; i < steps; i++ {
+					y = f(y)
+					q = mulMod(q, absDiff(x, y), n)
+				}
+				g = gcd(q, n)
+			}
+			r *= 2
+		}
+
+		if g == n {
+			// q collapsed to 0 mod n; step one at a time from the last
+			// checkpoint to recover a genuine factor.
+			for {
+				ys = f(ys)
+				g = gcd(absDiff(x, ys), n)
+				if g > 1 {
+					break
+				}
+			}
+		}
+
+		if g != n {
+			return g, nil
+		}
+	}
+
+	// Unreachable for composite n given isPrime already ruled out primality,
+	// but return n itself rather than looping forever.
+	return n, nil
+}
+
+// absDiff returns |a-b| without relying on signed overflow.
+func absDiff(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// mulMod returns a*b mod m, using math/big for the 128-bit intermediate
+// product so it doesn't overflow for a, b, m close to 2^64.
+func mulMod(a, b, m uint64) uint64 {
+	product := new(big.Int).Mul(new(big.Int).SetUint64(a), new(big.Int).SetUint64(b))
+	return product.Mod(product, new(big.Int).SetUint64(m)).Uint64()
+}
+
+// powMod returns base^exp mod m.
+func powMod(base, exp, m uint64) uint64 {
+	result := uint64(1)
+	base %= m
+	for exp > 0 {
+		if exp&1 == 1 {
+			result = mulMod(result, base, m)
+		}
+		base = mulMod(base, base, m)
+		exp >>= 1
+	}
+	return result
+}
+
+// sieve returns every prime up to and including limit via the Sieve of
+// Eratosthenes.
+func sieve(limit int) []int64 {
+	isComposite := make([]bool, limit+1)
+	var primes []int64
+	for i := 2; i <= limit; i++ {
+		if isComposite[i] {
+			continue
+		}
+		primes = append(primes, int64(i))
+		for j := i * i; j <= limit; j += i {
+			isComposite[j] = true
+		}
+	}
+	return primes
+}
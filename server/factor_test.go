@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFactorKnownFactorizations(t *testing.T) {
+	cases := []struct {
+		name string
+		nr   int64
+	}{
+		{"one", 1},
+		{"small prime", 13},
+		{"semiprime of two small primes", 15},
+		{"semiprime of two large primes", 1000000007 * 1000000009},
+		{"prime square", 999999937 * 999999937},
+		{"carmichael number 561", 561},
+		{"carmichael number 41041", 41041},
+		{"highly composite", 720720},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			divisors, err := factor(tc.nr)
+			if err != nil {
+				t.Fatalf("factor(%d) returned error: %v", tc.nr, err)
+			}
+			if len(divisors) == 0 || divisors[len(divisors)-1] != tc.nr {
+				t.Fatalf("factor(%d) = %v, last divisor should be nr itself", tc.nr, divisors)
+			}
+			if divisors[0] != 1 {
+				t.Fatalf("factor(%d) = %v, first divisor should be 1", tc.nr, divisors)
+			}
+			for _, d := range divisors {
+				if tc.nr%d != 0 {
+					t.Fatalf("factor(%d) = %v, %d does not divide %d", tc.nr, divisors, d, tc.nr)
+				}
+			}
+		})
+	}
+}
+
+func TestFactorRejectsNonPositive(t *testing.T) {
+	if _, err := factor(0); err == nil {
+		t.Fatal("factor(0) should return an error")
+	}
+}
+
+func TestFactorContextCancellation(t *testing.T) {
+	// A large semiprime with no small factors forces factorContext past trial
+	// division into factorizeLargeCofactor, where an already-canceled context
+	// should abort instead of running the computation to completion.
+	const largeSemiprime = 999999937 * 999999893
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := factorContext(ctx, largeSemiprime); err != context.Canceled {
+		t.Fatalf("factorContext with a canceled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestPollardRhoTerminates(t *testing.T) {
+	// Guards the "unreachable" fallback in pollardRho: every composite
+	// cofactor here must yield a proper, non-trivial factor instead of
+	// falling back to returning n itself.
+	composites := []uint64{
+		15, 21, 35, 77, 91,
+		561,   // Carmichael number
+		41041, // Carmichael number
+		1000000007 * 1000000009,
+		999999937 * 999999937,
+	}
+
+	for _, n := range composites {
+		d, err := pollardRho(context.Background(), n)
+		if err != nil {
+			t.Fatalf("pollardRho(%d) returned error: %v", n, err)
+		}
+		if d <= 1 || d >= n || n%d != 0 {
+			t.Fatalf("pollardRho(%d) = %d, want a proper divisor of n", n, d)
+		}
+	}
+}
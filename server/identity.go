@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userInfoContextKey is the gin.Context key tokenReviewMiddleware stores the
+// authenticated identity under. It is a plain string key: keeping the
+// constant unexported hides its name but not the entry, which any code
+// holding the gin.Context can reach with the same string.
+// withUserInfo/userInfoFromContext are this package's accessors for it.
+const userInfoContextKey = "userInfo"
+
+// withUserInfo stores the authenticated identity on ctx for downstream
+// handlers and middleware.
+func withUserInfo(ctx *gin.Context, user *UserInfo) {
+	ctx.Set(userInfoContextKey, user)
+}
+
+// userInfoFromContext retrieves the identity stored by tokenReviewMiddleware.
+// ok is false if the request was never authenticated.
+func userInfoFromContext(ctx *gin.Context) (user *UserInfo, ok bool) {
+	v, exists := ctx.Get(userInfoContextKey)
+	if !exists {
+		return nil, false
+	}
+	user, ok = v.(*UserInfo)
+	return user, ok
+}
+
+// requireGroup returns a middleware that must run after tokenReviewMiddleware
+// and aborts with 403 unless the authenticated identity belongs to group.
+func requireGroup(group string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := userInfoFromContext(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errNotAuthenticated))
+			return
+		}
+
+		for _, g := range user.Groups {
+			if g == group {
+				ctx.Next()
+				return
+			}
+		}
+
+		err := fmt.Errorf("user %q is not a member of group %q", user.Username, group)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+	}
+}
+
+// requireUser returns a middleware that must run after tokenReviewMiddleware
+// and aborts with 403 unless the authenticated identity's username matches
+// username.
+func requireUser(username string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := userInfoFromContext(ctx)
+		if !ok || user.Username != username {
+			err := fmt.Errorf("user is not %q", username)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.Next()
+	}
+}
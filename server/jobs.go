@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// defaultSyncMaxInput bounds how large an nr the synchronous /factor
+// endpoint will compute before telling the caller to use the async job API
+// instead.
+const defaultSyncMaxInput = int64(1) << 40
+
+// syncMaxInput is the effective synchronous-endpoint threshold, overridable
+// at startup via Config.SyncMaxInput.
+var syncMaxInput = defaultSyncMaxInput
+
+const (
+	defaultJobWorkers    = 4
+	defaultJobQueueSize  = 100
+	defaultJobMaxPerUser = 5
+)
+
+var (
+	errJobNotFound   = errors.New("job not found")
+	errQuotaExceeded = errors.New("per-user job quota exceeded")
+	errQueueFull     = errors.New("job queue is full, try again later")
+)
+
+// JobStatus is the lifecycle state of an async factorization job.
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job tracks one async factorization request. Its mutex guards the fields
+// the worker pool and HTTP handlers both touch.
+type Job struct {
+	ID    string
+	Owner string
+	NR    int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	status JobStatus
+	result []int64
+	err    error
+}
+
+func (j *Job) snapshot() (status JobStatus, result []int64, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status, j.result, j.err
+}
+
+func (j *Job) setStatus(status JobStatus) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status = status
+}
+
+// finish records a job's outcome, unless it was already canceled: Cancel
+// and finish both write j.status under j.mu, so whichever runs first wins
+// instead of finish unconditionally clobbering a recorded cancellation.
+func (j *Job) finish(result []int64, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status == JobFailed {
+		return
+	}
+	if err != nil {
+		j.status = JobFailed
+		j.err = err
+		return
+	}
+	j.status = JobDone
+	j.result = result
+}
+
+// JobStore persists jobs so they can be looked up by ID. The interface
+// lets the in-memory default be swapped for a shared store (e.g.
+// Redis/etcd) without touching JobManager.
+type JobStore interface {
+	Save(job *Job)
+	Get(id string) (*Job, bool)
+}
+
+// memoryJobStore is the default JobStore: a mutex-protected map.
+type memoryJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*Job
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{jobs: make(map[string]*Job)}
+}
+
+func (s *memoryJobStore) Save(job *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+}
+
+func (s *memoryJobStore) Get(id string) (*Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+// JobManager runs submitted jobs on a bounded worker pool and enforces a
+// per-owner quota so one user can't starve the queue for everyone else.
+type JobManager struct {
+	store JobStore
+	queue chan *Job
+
+	maxPerUser int
+
+	mu      sync.Mutex
+	perUser map[string]int
+}
+
+// newJobManager starts workers goroutines consuming from a queue of size
+// queueSize, and caps each owner at maxPerUser concurrently pending/running
+// jobs.
+func newJobManager(store JobStore, workers, queueSize, maxPerUser int) *JobManager {
+	jm := &JobManager{
+		store:      store,
+		queue:      make(chan *Job, queueSize),
+		maxPerUser: maxPerUser,
+		perUser:    make(map[string]int),
+	}
+	for i := 0; i < workers; i++ {
+		go jm.worker()
+	}
+	return jm
+}
+
+// Submit enqueues a factorization of nr on behalf of owner, returning the
+// created Job, or an error if owner is over quota or the queue is full.
+func (jm *JobManager) Submit(owner string, nr int64) (*Job, error) {
+	jm.mu.Lock()
+	if jm.perUser[owner] >= jm.maxPerUser {
+		jm.mu.Unlock()
+		return nil, errQuotaExceeded
+	}
+	jm.perUser[owner]++
+	jm.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		ID:     newJobID(),
+		Owner:  owner,
+		NR:     nr,
+		ctx:    ctx,
+		cancel: cancel,
+		status: JobPending,
+	}
+	jm.store.Save(job)
+
+	select {
+	case jm.queue <- job:
+		return job, nil
+	default:
+		cancel()
+		jm.releaseQuota(owner)
+		return nil, errQueueFull
+	}
+}
+
+// Get returns the job with id, if known.
+func (jm *JobManager) Get(id string) (*Job, bool) {
+	return jm.store.Get(id)
+}
+
+// Cancel stops job id if it hasn't finished yet and is owned by owner. A
+// job owned by someone else is reported as errJobNotFound, the same as a
+// job that doesn't exist, so a caller can't probe for other users' job IDs.
+func (jm *JobManager) Cancel(id, owner string) error {
+	job, ok := jm.store.Get(id)
+	if !ok || job.Owner != owner {
+		return errJobNotFound
+	}
+
+	job.cancel()
+	job.mu.Lock()
+	if job.status == JobPending || job.status == JobRunning {
+		job.status = JobFailed
+		job.err = errors.New("job canceled")
+	}
+	job.mu.Unlock()
+
+	return nil
+}
+
+func (jm *JobManager) releaseQuota(owner string) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+	jm.perUser[owner]--
+}
+
+// worker runs queued jobs until the queue is closed.
+func (jm *JobManager) worker() {
+	for job := range jm.queue {
+		jm.run(job)
+		jm.releaseQuota(job.Owner)
+	}
+}
+
+func (jm *JobManager) run(job *Job) {
+	if status, _, _ := job.snapshot(); status == JobFailed {
+		return // canceled before a worker picked it up
+	}
+	job.setStatus(JobRunning)
+
+	factors, err := factorContext(job.ctx, job.NR)
+	job.finish(factors, err) // no-ops if Cancel already marked the job failed
+}
+
+// newJobID generates a random per-job identifier.
+func newJobID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("job-%p", b)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createJobResponse is returned by POST /factor/jobs.
+type createJobResponse struct {
+	JobID     string `json:"job_id"`
+	StatusURL string `json:"status_url"`
+}
+
+// jobStatusResponse is returned by GET /factor/jobs/:id.
+type jobStatusResponse struct {
+	JobID   string    `json:"job_id"`
+	Status  JobStatus `json:"status"`
+	Factors []int64   `json:"factors,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// createFactorJobHandler submits a factorization job and returns 202 with a
+// status URL the caller can poll.
+func (server *Server) createFactorJobHandler(ctx *gin.Context) {
+	var req factorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	job, err := server.jobs.Submit(jobOwner(ctx), req.NR)
+	if err != nil {
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, createJobResponse{
+		JobID:     job.ID,
+		StatusURL: "/factor/jobs/" + job.ID,
+	})
+}
+
+// getFactorJobHandler reports a job's status and, once done, its result.
+// Jobs are scoped to their owner: a job belonging to someone else is
+// reported as not found rather than leaking its existence via a 403.
+func (server *Server) getFactorJobHandler(ctx *gin.Context) {
+	job, ok := server.jobs.Get(ctx.Param("id"))
+	if !ok || job.Owner != jobOwner(ctx) {
+		ctx.JSON(http.StatusNotFound, errorResponse(errJobNotFound))
+		return
+	}
+
+	status, result, err := job.snapshot()
+	resp := jobStatusResponse{JobID: job.ID, Status: status, Factors: result}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// cancelFactorJobHandler cancels a pending or running job owned by the
+// caller.
+func (server *Server) cancelFactorJobHandler(ctx *gin.Context) {
+	if err := server.jobs.Cancel(ctx.Param("id"), jobOwner(ctx)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errJobNotFound) {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// jobOwner returns the username to bill a job against, falling back to a
+// shared bucket for backends that don't populate an identity.
+func jobOwner(ctx *gin.Context) string {
+	if user, ok := userInfoFromContext(ctx); ok && user.Username != "" {
+		return user.Username
+	}
+	return "anonymous"
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// traceIDContextKey is the gin.Context key requestLogger stores the
+// per-request trace ID under.
+const traceIDContextKey = "traceID"
+
+// metricsMiddleware records request latency, status and in-flight count for
+// every request using the histograms/gauges in metrics.go.
+func metricsMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		inFlightRequests.Inc()
+		defer inFlightRequests.Dec()
+
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		requestDuration.WithLabelValues(route, strconv.Itoa(ctx.Writer.Status())).Observe(time.Since(start).Seconds())
+	}
+}
+
+// requestLogger returns a middleware that replaces gin's default logger
+// with structured logging via zap: a trace ID, the authenticated username
+// (once tokenReviewMiddleware has run), the remote IP and request latency.
+func requestLogger(logger *zap.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		traceID, err := newTraceID()
+		if err != nil {
+			traceID = "unknown"
+		}
+		ctx.Set(traceIDContextKey, traceID)
+
+		ctx.Next()
+
+		username := "-"
+		if user, ok := userInfoFromContext(ctx); ok {
+			username = user.Username
+		}
+
+		logger.Info("request",
+			zap.String("trace_id", traceID),
+			zap.String("username", username),
+			zap.String("remote_ip", ctx.ClientIP()),
+			zap.String("method", ctx.Request.Method),
+			zap.String("path", ctx.FullPath()),
+			zap.Int("status", ctx.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+		)
+	}
+}
+
+// newTraceID generates a random per-request identifier.
+func newTraceID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
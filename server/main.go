@@ -2,12 +2,15 @@ package main
 
 import (
 	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	authenticationv1 "k8s.io/api/authentication/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -19,12 +22,46 @@ const (
 )
 
 func main() {
-	clientset, err := setupKubeClient()
+	configPath := flag.String("config", "", "path to the server config YAML file")
+	metricsAddr := flag.String("metrics-addr", "", "address to serve /metrics on; disabled if empty")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("cannot load config: %s", err)
+	}
+
+	authenticator, err := newAuthenticator(cfg)
+	if err != nil {
+		log.Fatalf("cannot build authenticator: %s", err)
+	}
+
+	if cfg.FactorMaxDivisors > 0 {
+		maxDivisors = cfg.FactorMaxDivisors
+	}
+	if cfg.SyncMaxInput > 0 {
+		syncMaxInput = cfg.SyncMaxInput
+	}
+
+	logger, err := zap.NewProduction()
 	if err != nil {
-		log.Fatalf("cannot create k8s clientset: %s", err)
+		log.Fatalf("cannot build logger: %s", err)
+	}
+	defer logger.Sync()
+
+	if *metricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.Handler())
+			if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+				logger.Error("metrics server stopped", zap.Error(err))
+			}
+		}()
 	}
 
-	srv := newServer(clientset)
+	jobs := newJobManager(newMemoryJobStore(), cfg.Jobs.Workers, cfg.Jobs.QueueSize, cfg.Jobs.MaxPerUser)
+
+	srv := newServer(authenticator, logger, jobs)
 	err = srv.start(":8080")
 	if err != nil {
 		log.Fatalf("cannot start server: %s", err)
@@ -32,13 +69,17 @@ func main() {
 }
 
 type Server struct {
-	router    *gin.Engine
-	clientset *kubernetes.Clientset
+	router        *gin.Engine
+	authenticator Authenticator
+	logger        *zap.Logger
+	jobs          *JobManager
 }
 
-func newServer(clientset *kubernetes.Clientset) *Server {
+func newServer(authenticator Authenticator, logger *zap.Logger, jobs *JobManager) *Server {
 	server := &Server{
-		clientset: clientset,
+		authenticator: authenticator,
+		logger:        logger,
+		jobs:          jobs,
 	}
 	server.setupRouter()
 	return server
@@ -65,11 +106,18 @@ func (server *Server) start(addr string) error {
 	return server.router.Run(addr)
 }
 
-// setupRouter use tokenReviewMiddleware and setup router
+// setupRouter use tokenReviewMiddleware and setup router. Handlers that need
+// stricter authorization than "any authenticated identity" can additionally
+// chain requireGroup/requireUser after tokenReviewMiddleware.
 func (server *Server) setupRouter() {
-	router := gin.Default()
-	authRoutes := router.Group("/").Use(tokenReviewMiddleware(server.clientset))
+	router := gin.New()
+	router.Use(gin.Recovery(), metricsMiddleware(), requestLogger(server.logger))
+
+	authRoutes := router.Group("/").Use(tokenReviewMiddleware(server.authenticator))
 	authRoutes.POST("/factor", server.factorHandler)
+	authRoutes.POST("/factor/jobs", server.createFactorJobHandler)
+	authRoutes.GET("/factor/jobs/:id", server.getFactorJobHandler)
+	authRoutes.DELETE("/factor/jobs/:id", server.cancelFactorJobHandler)
 	server.router = router
 }
 
@@ -89,7 +137,15 @@ func (server *Server) factorHandler(ctx *gin.Context) {
 		return
 	}
 
+	if req.NR > syncMaxInput {
+		err := fmt.Errorf("nr exceeds the synchronous limit of %d; submit it to POST /factor/jobs instead", syncMaxInput)
+		ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(err))
+		return
+	}
+
+	start := time.Now()
 	factors, err := factor(req.NR)
+	factorDuration.WithLabelValues(magnitudeBucket(req.NR)).Observe(time.Since(start).Seconds())
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -104,8 +160,9 @@ func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
 
-// tokenReviewMiddleware validate the token
-func tokenReviewMiddleware(clienset *kubernetes.Clientset) gin.HandlerFunc {
+// tokenReviewMiddleware authenticates the request token using the
+// configured Authenticator backend, aborting with 401/403 on failure.
+func tokenReviewMiddleware(authenticator Authenticator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
@@ -114,89 +171,19 @@ func tokenReviewMiddleware(clienset *kubernetes.Clientset) gin.HandlerFunc {
 			return
 		}
 
-		if ok, err := tokenReviewRequest(clienset, ctx, authorizationHeader); !ok {
+		user, err := authenticator.Authenticate(ctx, authorizationHeader)
+		if err != nil {
+			result := "error"
+			if errors.Is(err, errNotAuthenticated) {
+				result = "unauthenticated"
+			}
+			tokenReviewsTotal.WithLabelValues(result).Inc()
 			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
 			return
 		}
 
+		tokenReviewsTotal.WithLabelValues("authenticated").Inc()
+		withUserInfo(ctx, user)
 		ctx.Next()
 	}
 }
-
-// tokenReviewRequest attempts to authenticate a token to a known user.
-func tokenReviewRequest(clientset *kubernetes.Clientset, ctx *gin.Context, token string) (bool, error) {
-	tokenReview := &authenticationv1.TokenReview{
-		Spec: authenticationv1.TokenReviewSpec{
-			Token: token,
-		},
-	}
-
-	review, err := clientset.AuthenticationV1().TokenReviews().Create(ctx, tokenReview, metav1.CreateOptions{})
-	audiences := review.Status.Audiences
-
-	return review.Status.Authenticated && validateAudiences(audiences), err
-}
-
-// validateAudiences validate audience is in APIServer option `--api-audiences` values
-func validateAudiences(audiences []string) bool {
-	for _, v := range audiences {
-		if v == audience {
-			return true
-		}
-		continue
-	}
-	return false
-}
-
-// factor calculates all factors of a given positive integer nr.
-// It uses an efficient approach based on prime factorization,
-// which reduces the number of operations compared to checking every number in a loop.
-// The function returns a slice of int64 containing the factors and an error if the input is not valid.
-func factor(nr int64) ([]int64, error) {
-	// Initialize an int64 slice with one element and an error check for positive integers
-	fs := make([]int64, 1)
-	if nr < 1 {
-		return fs, errors.New("factors of 0 not computed, please provide a positive integer greater than 0")
-	}
-
-	fs[0] = 1
-
-	// Helper function to append prime factors and their multiples
-	apf := func(p int64, e int) {
-		n := len(fs)
-		for i, pp := 0, p; i < e; i, pp = i+1, pp*p {
-			for j := 0; j < n; j++ {
-				fs = append(fs, fs[j]*pp)
-			}
-		}
-	}
-
-	// Extract all factors of 2
-	e := 0
-	for ; nr&1 == 0; e++ {
-		nr >>= 1
-	}
-
-	// Append factors of 2
-	apf(2, e)
-
-	// Extract and append other prime factors and their multiples
-	for d := int64(3); nr > 1; d += 2 {
-		// If d*d is greater than nr, set d to nr (it means nr is prime)
-		if d*d > nr {
-			d = nr
-		}
-
-		// Count the number of times nr is divisible by d
-		for e = 0; nr%d == 0; e++ {
-			nr /= d
-		}
-
-		// Append prime factors and their multiples
-		if e > 0 {
-			apf(d, e)
-		}
-	}
-
-	return fs, nil
-}
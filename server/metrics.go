@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests, labeled by route and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "status"})
+
+	tokenReviewsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tokenreview_requests_total",
+		Help: "TokenReview outcomes, labeled by result.",
+	}, []string{"result"})
+
+	factorDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "factor_compute_duration_seconds",
+		Help:    "Latency of factor() calls, bucketed by the input's order of magnitude.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"magnitude"})
+
+	inFlightRequests = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_in_flight_requests",
+		Help: "Number of HTTP requests currently being served.",
+	})
+)
+
+// magnitudeBucket labels nr by its decimal digit count so the factor-compute
+// histogram buckets by rough input size instead of one label per value.
+func magnitudeBucket(nr int64) string {
+	if nr < 1 {
+		return "0"
+	}
+	return strconv.Itoa(len(strconv.FormatInt(nr, 10)))
+}